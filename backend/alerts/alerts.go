@@ -0,0 +1,32 @@
+package alerts
+
+import (
+	"context"
+	"time"
+)
+
+// EventKind distinguishes the situations a Sink can be notified about.
+type EventKind string
+
+const (
+	EventKindSessionInitError EventKind = "session_init_error"
+	EventKindError            EventKind = "error"
+)
+
+// Event is the payload handed to every enabled Sink for an organization.
+type Event struct {
+	Kind           EventKind
+	OrganizationID int
+	SessionID      int
+	Identifier     string
+	URL            string
+	Title          string
+	Message        string
+	Timestamp      time.Time
+}
+
+// Sink delivers an Event to a destination (Slack, Discord, PagerDuty, Teams, or a generic
+// webhook). Implementations should treat ctx's deadline as authoritative for any outbound call.
+type Sink interface {
+	Notify(ctx context.Context, event Event) error
+}
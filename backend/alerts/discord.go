@@ -0,0 +1,46 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	e "github.com/pkg/errors"
+)
+
+// DiscordSink delivers alerts via a Discord incoming webhook.
+type DiscordSink struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func NewDiscordSink(webhookURL string) *DiscordSink {
+	return &DiscordSink{WebhookURL: webhookURL, Client: http.DefaultClient}
+}
+
+func (s *DiscordSink) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(map[string]string{
+		"content": fmt.Sprintf("**%s**\n%s", event.Title, event.Message),
+	})
+	if err != nil {
+		return e.Wrap(err, "error marshaling discord payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return e.Wrap(err, "error creating discord request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return e.Wrap(err, "error posting discord webhook")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("discord webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package alerts
+
+import (
+	"context"
+	"sync"
+)
+
+// RouterLoader loads the enabled SinkConfigs for an organization, typically backed by a DB
+// table of per-organization alert destinations.
+type RouterLoader func(organizationID int) ([]SinkConfig, error)
+
+// Manager caches a per-organization Router so PushPayload and InitializeSession don't reload
+// alert config on every call. Use Default for events raised before an organization is known
+// (e.g. InitializeSession failing before the session's org has been resolved).
+type Manager struct {
+	Load    RouterLoader
+	Default *Router
+
+	mu      sync.Mutex
+	routers map[int]*Router
+}
+
+func NewManager(load RouterLoader, defaultRouter *Router) *Manager {
+	return &Manager{Load: load, Default: defaultRouter, routers: make(map[int]*Router)}
+}
+
+// Notify fans event out to every sink enabled for organizationID, lazily loading and caching
+// the organization's Router on first use.
+func (m *Manager) Notify(ctx context.Context, organizationID int, event Event) error {
+	router, err := m.routerFor(organizationID)
+	if err != nil {
+		return err
+	}
+	router.Notify(ctx, event)
+	return nil
+}
+
+// NotifyDefault fans event out to the fallback router, for situations where no organization is
+// known yet.
+func (m *Manager) NotifyDefault(ctx context.Context, event Event) {
+	if m.Default == nil {
+		return
+	}
+	m.Default.Notify(ctx, event)
+}
+
+func (m *Manager) routerFor(organizationID int) (*Router, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if router, ok := m.routers[organizationID]; ok {
+		return router, nil
+	}
+
+	sinks, err := m.Load(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	router := NewRouter(organizationID, sinks)
+	m.routers[organizationID] = router
+	return router, nil
+}
@@ -0,0 +1,66 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	e "github.com/pkg/errors"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySink delivers alerts via the PagerDuty Events API v2.
+type PagerDutySink struct {
+	RoutingKey string
+	Client     *http.Client
+}
+
+func NewPagerDutySink(routingKey string) *PagerDutySink {
+	return &PagerDutySink{RoutingKey: routingKey, Client: http.DefaultClient}
+}
+
+type pagerDutyPayload struct {
+	RoutingKey  string                `json:"routing_key"`
+	EventAction string                `json:"event_action"`
+	Payload     pagerDutyEventPayload `json:"payload"`
+}
+
+type pagerDutyEventPayload struct {
+	Summary  string `json:"summary"`
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+}
+
+func (s *PagerDutySink) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(pagerDutyPayload{
+		RoutingKey:  s.RoutingKey,
+		EventAction: "trigger",
+		Payload: pagerDutyEventPayload{
+			Summary:  fmt.Sprintf("%s: %s", event.Title, event.Message),
+			Source:   event.URL,
+			Severity: "error",
+		},
+	})
+	if err != nil {
+		return e.Wrap(err, "error marshaling pagerduty payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pagerDutyEventsURL, bytes.NewReader(body))
+	if err != nil {
+		return e.Wrap(err, "error creating pagerduty request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return e.Wrap(err, "error posting pagerduty event")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pagerduty events api returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package alerts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// SinkConfig is one row of an organization's alert configuration, as loaded from the DB.
+type SinkConfig struct {
+	Sink       Sink
+	Name       string
+	RatePerSec float64
+	RetryCount int
+}
+
+// Router fans an Event out to every enabled Sink for an organization, rate limiting and
+// retrying each sink independently so one misconfigured destination can't starve the rest.
+type Router struct {
+	OrganizationID int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	sinks    []SinkConfig
+}
+
+// NewRouter builds a Router for the given organization's enabled sink configs.
+func NewRouter(organizationID int, sinks []SinkConfig) *Router {
+	limiters := make(map[string]*rate.Limiter, len(sinks))
+	for _, s := range sinks {
+		limit := s.RatePerSec
+		if limit <= 0 {
+			limit = 1
+		}
+		limiters[s.Name] = rate.NewLimiter(rate.Limit(limit), 1)
+	}
+	return &Router{OrganizationID: organizationID, limiters: limiters, sinks: sinks}
+}
+
+// Notify delivers event to every configured sink, retrying with backoff and honoring each
+// sink's rate limit. Errors from individual sinks are logged, not returned, so one bad
+// destination can't block delivery to the others or the caller.
+func (router *Router) Notify(ctx context.Context, event Event) {
+	for _, sink := range router.sinks {
+		sink := sink
+		if err := router.limiters[sink.Name].Wait(ctx); err != nil {
+			log.WithError(err).WithField("sink", sink.Name).Warn("alerts: rate limiter wait failed")
+			continue
+		}
+
+		retries := sink.RetryCount
+		if retries <= 0 {
+			retries = 3
+		}
+		if err := notifyWithRetry(ctx, sink.Sink, event, retries); err != nil {
+			log.WithError(err).WithField("sink", sink.Name).Error("alerts: error notifying sink")
+		}
+	}
+}
+
+func notifyWithRetry(ctx context.Context, sink Sink, event Event, attempts int) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = sink.Notify(ctx, event); err == nil {
+			return nil
+		}
+		select {
+		case <-time.After(time.Duration(1<<uint(i)) * 200 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return e.Wrap(err, "alerts: sink notify failed after retries")
+}
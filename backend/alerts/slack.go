@@ -0,0 +1,26 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+
+	e "github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// SlackSink delivers alerts via an incoming Slack webhook.
+type SlackSink struct {
+	WebhookURL string
+}
+
+func NewSlackSink(webhookURL string) *SlackSink {
+	return &SlackSink{WebhookURL: webhookURL}
+}
+
+func (s *SlackSink) Notify(ctx context.Context, event Event) error {
+	msg := slack.WebhookMessage{Text: fmt.Sprintf("%s\n%s", event.Title, event.Message)}
+	if err := slack.PostWebhookContext(ctx, s.WebhookURL, &msg); err != nil {
+		return e.Wrap(err, "error posting slack webhook")
+	}
+	return nil
+}
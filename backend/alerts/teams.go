@@ -0,0 +1,50 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	e "github.com/pkg/errors"
+)
+
+// TeamsSink delivers alerts via a Microsoft Teams incoming webhook connector.
+type TeamsSink struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func NewTeamsSink(webhookURL string) *TeamsSink {
+	return &TeamsSink{WebhookURL: webhookURL, Client: http.DefaultClient}
+}
+
+func (s *TeamsSink) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"title":      event.Title,
+		"text":       event.Message,
+		"themeColor": "FF0000",
+	})
+	if err != nil {
+		return e.Wrap(err, "error marshaling teams payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return e.Wrap(err, "error creating teams request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return e.Wrap(err, "error posting teams webhook")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("teams webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	e "github.com/pkg/errors"
+)
+
+// WebhookSink delivers alerts as an HMAC-SHA256-signed JSON POST, for destinations that aren't
+// one of the named integrations in this package (Slack, Discord, PagerDuty, Teams).
+type WebhookSink struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{URL: url, Secret: secret, Client: http.DefaultClient}
+}
+
+func (s *WebhookSink) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return e.Wrap(err, "error marshaling webhook payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return e.Wrap(err, "error creating webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Highlight-Signature", s.sign(body))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return e.Wrap(err, "error posting webhook")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *WebhookSink) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
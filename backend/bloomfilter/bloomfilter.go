@@ -0,0 +1,75 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"sync"
+
+	bloom "github.com/bits-and-blooms/bloom/v3"
+)
+
+// expectedIdentifiers sizes the filter for bloom.NewWithEstimates; orgs that blow past this
+// simply see a rising false-positive rate (more unnecessary DB fallbacks), never incorrect
+// "first time" results.
+const expectedIdentifiers = 10_000_000
+const falsePositiveRate = 0.01
+
+// IdentifierFilter is a per-organization Bloom filter of every identifier IdentifySession has
+// ever seen. A "not present" result is certain; a "maybe present" result still requires the
+// authoritative DB lookup.
+type IdentifierFilter struct {
+	mu     sync.RWMutex
+	filter *bloom.BloomFilter
+	dirty  bool
+}
+
+func NewIdentifierFilter() *IdentifierFilter {
+	return &IdentifierFilter{filter: bloom.NewWithEstimates(expectedIdentifiers, falsePositiveRate)}
+}
+
+// MaybeSeen reports whether identifier may have been added before. false means certainly not.
+func (f *IdentifierFilter) MaybeSeen(identifier string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.filter.Test([]byte(identifier))
+}
+
+// Add records identifier as seen.
+func (f *IdentifierFilter) Add(identifier string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filter.Add([]byte(identifier))
+	f.dirty = true
+}
+
+// Marshal serializes the filter for persistence (e.g. to Redis).
+func (f *IdentifierFilter) Marshal() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var buf bytes.Buffer
+	if _, err := f.filter.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal replaces the filter's contents with a previously-Marshaled snapshot.
+func (f *IdentifierFilter) Unmarshal(data []byte) error {
+	filter := bloom.NewWithEstimates(expectedIdentifiers, falsePositiveRate)
+	if _, err := filter.ReadFrom(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filter = filter
+	f.dirty = false
+	return nil
+}
+
+// TakeDirty reports and clears whether Add has been called since the last flush.
+func (f *IdentifierFilter) TakeDirty() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dirty := f.dirty
+	f.dirty = false
+	return dirty
+}
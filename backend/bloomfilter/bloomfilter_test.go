@@ -0,0 +1,58 @@
+package bloomfilter
+
+import "testing"
+
+func TestIdentifierFilterAddAndMaybeSeen(t *testing.T) {
+	f := NewIdentifierFilter()
+
+	if f.MaybeSeen("alice@example.com") {
+		t.Fatal("MaybeSeen on an empty filter must be false")
+	}
+
+	f.Add("alice@example.com")
+
+	if !f.MaybeSeen("alice@example.com") {
+		t.Error("MaybeSeen is false for an identifier that was Add-ed")
+	}
+}
+
+func TestIdentifierFilterTakeDirty(t *testing.T) {
+	f := NewIdentifierFilter()
+
+	if f.TakeDirty() {
+		t.Fatal("a freshly constructed filter must not be dirty")
+	}
+
+	f.Add("bob@example.com")
+	if !f.TakeDirty() {
+		t.Error("TakeDirty must report true right after Add")
+	}
+	if f.TakeDirty() {
+		t.Error("TakeDirty must clear the dirty flag, so a second call returns false")
+	}
+}
+
+func TestIdentifierFilterMarshalUnmarshalRoundTrip(t *testing.T) {
+	f := NewIdentifierFilter()
+	f.Add("carol@example.com")
+
+	data, err := f.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	restored := NewIdentifierFilter()
+	if err := restored.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+
+	if !restored.MaybeSeen("carol@example.com") {
+		t.Error("restored filter should still report the identifier as maybe-seen")
+	}
+	if restored.MaybeSeen("dave@example.com") {
+		t.Error("restored filter should not report an identifier that was never added")
+	}
+	if restored.TakeDirty() {
+		t.Error("Unmarshal must clear dirty, since the restored filter exactly matches the persisted snapshot")
+	}
+}
@@ -0,0 +1,41 @@
+package bloomfilter
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	e "github.com/pkg/errors"
+)
+
+// RedisFlushStore persists filter snapshots to Redis under redisKey(organizationID), so a
+// process restart can reload a recently-flushed filter instead of paying Rebuilder's full
+// sessions-table scan on every org's first request.
+type RedisFlushStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisFlushStore builds a RedisFlushStore. ttl bounds how long a flushed snapshot is
+// trusted before Load treats it as missing and falls back to Rebuilder; pass 0 to never expire.
+func NewRedisFlushStore(client *redis.Client, ttl time.Duration) *RedisFlushStore {
+	return &RedisFlushStore{client: client, ttl: ttl}
+}
+
+func (s *RedisFlushStore) Save(ctx context.Context, organizationID int, data []byte) error {
+	if err := s.client.Set(ctx, redisKey(organizationID), data, s.ttl).Err(); err != nil {
+		return e.Wrap(err, "bloomfilter: error saving filter to redis")
+	}
+	return nil
+}
+
+func (s *RedisFlushStore) Load(ctx context.Context, organizationID int) ([]byte, error) {
+	data, err := s.client.Get(ctx, redisKey(organizationID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, e.Wrap(err, "bloomfilter: error loading filter from redis")
+	}
+	return data, nil
+}
@@ -0,0 +1,133 @@
+package bloomfilter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// FlushStore persists and loads a per-organization filter snapshot, backed by Redis (or object
+// storage) in production.
+type FlushStore interface {
+	Save(ctx context.Context, organizationID int, data []byte) error
+	Load(ctx context.Context, organizationID int) ([]byte, error)
+}
+
+// Rebuilder lazily rebuilds an organization's filter from the authoritative `sessions` table
+// when no persisted snapshot is found (e.g. after a cold start).
+type Rebuilder func(ctx context.Context, organizationID int) (*IdentifierFilter, error)
+
+// Registry owns one IdentifierFilter per organization and periodically flushes dirty filters
+// to FlushStore so a process restart doesn't cold-start every org's filter.
+type Registry struct {
+	Store      FlushStore
+	Rebuild    Rebuilder
+	FlushEvery time.Duration
+
+	mu      sync.Mutex
+	filters map[int]*IdentifierFilter
+}
+
+func NewRegistry(store FlushStore, rebuild Rebuilder, flushEvery time.Duration) *Registry {
+	if flushEvery <= 0 {
+		flushEvery = time.Minute
+	}
+	return &Registry{Store: store, Rebuild: rebuild, FlushEvery: flushEvery, filters: make(map[int]*IdentifierFilter)}
+}
+
+// Get returns the filter for organizationID, loading a persisted snapshot or lazily rebuilding
+// from the sessions table if one isn't found.
+func (r *Registry) Get(ctx context.Context, organizationID int) (*IdentifierFilter, error) {
+	r.mu.Lock()
+	if f, ok := r.filters[organizationID]; ok {
+		r.mu.Unlock()
+		return f, nil
+	}
+	r.mu.Unlock()
+
+	filter, err := r.load(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.filters[organizationID]; ok {
+		return f, nil
+	}
+	r.filters[organizationID] = filter
+	return filter, nil
+}
+
+// load returns the organization's persisted filter snapshot, falling back to the expensive
+// Rebuild scan only when Store.Load reports there genuinely is no snapshot (nil data, nil
+// error) - the same convention RedisFlushStore.Load uses to distinguish redis.Nil from a real
+// error. A Store.Load error (e.g. a Redis connection failure) is returned to the caller instead
+// of silently triggering Rebuild, so a storage blip doesn't turn into every organization paying
+// a full sessions-table scan with no record of why.
+func (r *Registry) load(ctx context.Context, organizationID int) (*IdentifierFilter, error) {
+	data, err := r.Store.Load(ctx, organizationID)
+	if err != nil {
+		return nil, e.Wrap(err, "error loading persisted bloom filter")
+	}
+
+	if len(data) > 0 {
+		filter := NewIdentifierFilter()
+		if err := filter.Unmarshal(data); err != nil {
+			return nil, e.Wrap(err, "error unmarshaling persisted bloom filter")
+		}
+		return filter, nil
+	}
+
+	filter, err := r.Rebuild(ctx, organizationID)
+	if err != nil {
+		return nil, e.Wrap(err, "error rebuilding bloom filter from sessions table")
+	}
+	return filter, nil
+}
+
+// RunPeriodicFlush blocks, flushing every dirty filter to r.Store every r.FlushEvery, until ctx
+// is canceled.
+func (r *Registry) RunPeriodicFlush(ctx context.Context) {
+	ticker := time.NewTicker(r.FlushEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.flushAll(ctx)
+		}
+	}
+}
+
+func (r *Registry) flushAll(ctx context.Context) {
+	r.mu.Lock()
+	snapshot := make(map[int]*IdentifierFilter, len(r.filters))
+	for orgID, f := range r.filters {
+		snapshot[orgID] = f
+	}
+	r.mu.Unlock()
+
+	for orgID, f := range snapshot {
+		if !f.TakeDirty() {
+			continue
+		}
+		data, err := f.Marshal()
+		if err != nil {
+			log.WithError(err).Errorf("bloomfilter: error marshaling filter for org %d", orgID)
+			continue
+		}
+		if err := r.Store.Save(ctx, orgID, data); err != nil {
+			log.WithError(err).Errorf("bloomfilter: error flushing filter for org %d", orgID)
+		}
+	}
+}
+
+func redisKey(organizationID int) string {
+	return fmt.Sprintf("bloomfilter:identifiers:%d", organizationID)
+}
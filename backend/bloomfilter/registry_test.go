@@ -0,0 +1,78 @@
+package bloomfilter
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeFlushStore struct {
+	data []byte
+	err  error
+}
+
+func (s *fakeFlushStore) Save(ctx context.Context, organizationID int, data []byte) error {
+	return nil
+}
+
+func (s *fakeFlushStore) Load(ctx context.Context, organizationID int) ([]byte, error) {
+	return s.data, s.err
+}
+
+func TestRegistryLoadRebuildsOnMissingSnapshot(t *testing.T) {
+	rebuilt := false
+	r := NewRegistry(&fakeFlushStore{}, func(ctx context.Context, organizationID int) (*IdentifierFilter, error) {
+		rebuilt = true
+		return NewIdentifierFilter(), nil
+	}, 0)
+
+	if _, err := r.load(context.Background(), 1); err != nil {
+		t.Fatalf("load returned error: %v", err)
+	}
+	if !rebuilt {
+		t.Error("load must fall back to Rebuild when Store.Load returns no data and no error")
+	}
+}
+
+func TestRegistryLoadReturnsStoreErrorWithoutRebuilding(t *testing.T) {
+	rebuilt := false
+	storeErr := errors.New("redis: connection refused")
+	r := NewRegistry(&fakeFlushStore{err: storeErr}, func(ctx context.Context, organizationID int) (*IdentifierFilter, error) {
+		rebuilt = true
+		return NewIdentifierFilter(), nil
+	}, 0)
+
+	_, err := r.load(context.Background(), 1)
+	if err == nil {
+		t.Fatal("expected load to return an error when Store.Load fails")
+	}
+	if rebuilt {
+		t.Error("load must not fall back to the expensive Rebuild scan on a genuine Store.Load error")
+	}
+}
+
+func TestRegistryLoadUsesPersistedSnapshot(t *testing.T) {
+	seed := NewIdentifierFilter()
+	seed.Add("persisted@example.com")
+	data, err := seed.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	rebuilt := false
+	r := NewRegistry(&fakeFlushStore{data: data}, func(ctx context.Context, organizationID int) (*IdentifierFilter, error) {
+		rebuilt = true
+		return NewIdentifierFilter(), nil
+	}, 0)
+
+	filter, err := r.load(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("load returned error: %v", err)
+	}
+	if rebuilt {
+		t.Error("load must not call Rebuild when a persisted snapshot was found")
+	}
+	if !filter.MaybeSeen("persisted@example.com") {
+		t.Error("loaded filter should contain the identifier from the persisted snapshot")
+	}
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"github.com/highlight-run/highlight/backend/alerts"
+	"github.com/highlight-run/highlight/backend/model"
+	e "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// NewDBRouterLoader returns an alerts.RouterLoader backed by model.AlertSinkConfig, so each
+// organization's enabled alert destinations come from the sinks they've configured in the app
+// rather than the single hardcoded Slack webhook this used to be.
+func NewDBRouterLoader(db *gorm.DB) alerts.RouterLoader {
+	return func(organizationID int) ([]alerts.SinkConfig, error) {
+		var configs []model.AlertSinkConfig
+		if err := db.Where(&model.AlertSinkConfig{OrganizationID: organizationID, Enabled: true}).Find(&configs).Error; err != nil {
+			return nil, e.Wrap(err, "error loading alert sink configs")
+		}
+
+		sinks := make([]alerts.SinkConfig, 0, len(configs))
+		for _, cfg := range configs {
+			sink, err := sinkFromConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, alerts.SinkConfig{
+				Sink:       sink,
+				Name:       cfg.Name,
+				RatePerSec: cfg.RatePerSec,
+				RetryCount: cfg.RetryCount,
+			})
+		}
+		return sinks, nil
+	}
+}
+
+// sinkFromConfig builds the concrete alerts.Sink cfg.Kind describes.
+func sinkFromConfig(cfg model.AlertSinkConfig) (alerts.Sink, error) {
+	switch cfg.Kind {
+	case "slack":
+		return alerts.NewSlackSink(cfg.URL), nil
+	case "discord":
+		return alerts.NewDiscordSink(cfg.URL), nil
+	case "pagerduty":
+		return alerts.NewPagerDutySink(cfg.Secret), nil
+	case "teams":
+		return alerts.NewTeamsSink(cfg.URL), nil
+	case "webhook":
+		return alerts.NewWebhookSink(cfg.URL, cfg.Secret), nil
+	default:
+		return nil, e.Errorf("alerts: unknown sink kind %q", cfg.Kind)
+	}
+}
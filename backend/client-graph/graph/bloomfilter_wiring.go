@@ -0,0 +1,33 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/highlight-run/highlight/backend/bloomfilter"
+	"github.com/highlight-run/highlight/backend/model"
+	e "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// NewSessionsRebuilder returns a bloomfilter.Rebuilder that rebuilds an organization's filter
+// from every distinct identifier in the sessions table, for the cold-start/cache-miss path when
+// RedisFlushStore has no (or an expired) snapshot for that organization.
+func NewSessionsRebuilder(db *gorm.DB) bloomfilter.Rebuilder {
+	return func(ctx context.Context, organizationID int) (*bloomfilter.IdentifierFilter, error) {
+		var identifiers []string
+		if err := db.WithContext(ctx).
+			Model(&model.Session{}).
+			Where(&model.Session{OrganizationID: organizationID}).
+			Where("identifier != ''").
+			Distinct("identifier").
+			Pluck("identifier", &identifiers).Error; err != nil {
+			return nil, e.Wrap(err, "error loading identifiers to rebuild bloom filter")
+		}
+
+		filter := bloomfilter.NewIdentifierFilter()
+		for _, identifier := range identifiers {
+			filter.Add(identifier)
+		}
+		return filter, nil
+	}
+}
@@ -0,0 +1,291 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	parse "github.com/highlight-run/highlight/backend/event-parse"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/storage"
+	e "github.com/pkg/errors"
+)
+
+// chunkAckTTL bounds how long a session's chunk-ack bookkeeping survives without activity, so a
+// client that stops streaming chunks without ever calling FinalizePayload (a closed tab, a
+// crash, a dropped connection) doesn't leak its entry for the life of the process.
+const chunkAckTTL = 30 * time.Minute
+
+// chunkAckSweepInterval is how often the sweep looks for entries past chunkAckTTL.
+const chunkAckSweepInterval = 5 * time.Minute
+
+// chunkState tracks one (sessionID, seq) chunk: either a write is in flight (done == false, in
+// which case wait is closed when that write finishes, one way or the other) or the chunk has
+// been durably written (done == true).
+type chunkState struct {
+	done bool
+	wait chan struct{}
+}
+
+// chunkAcks remembers which (sessionID, seq) chunks are being written or have already been
+// durably written, so a client retrying a chunk it never got an ack for doesn't duplicate events
+// in the store, and two concurrent retries of the same chunk don't both write it. Entries for a
+// session are dropped once FinalizePayload is called for it, or after chunkAckTTL of inactivity,
+// so the set only grows with sessions currently (or recently) streaming, not every session ever
+// seen.
+type chunkAcks struct {
+	mu       sync.Mutex
+	state    map[int]map[int]*chunkState
+	lastSeen map[int]time.Time
+}
+
+var pushPayloadChunkAcks = &chunkAcks{state: make(map[int]map[int]*chunkState), lastSeen: make(map[int]time.Time)}
+
+func init() {
+	go pushPayloadChunkAcks.runSweep(context.Background(), chunkAckSweepInterval)
+}
+
+// begin reports whether the caller should write (sessionID, seq) itself. If another attempt at
+// the same chunk is already in flight, begin blocks until it resolves (success or failure) and
+// then re-checks, so concurrent retries serialize instead of racing. A caller that gets back
+// proceed == true must eventually call commit (on success) or abandon (on failure) for this
+// (sessionID, seq) so the next attempt - or a future retry - isn't blocked forever.
+func (a *chunkAcks) begin(ctx context.Context, sessionID, seq int) (proceed bool, err error) {
+	for {
+		a.mu.Lock()
+		a.lastSeen[sessionID] = time.Now()
+		seqs, ok := a.state[sessionID]
+		if !ok {
+			seqs = make(map[int]*chunkState)
+			a.state[sessionID] = seqs
+		}
+		st, ok := seqs[seq]
+		if !ok {
+			seqs[seq] = &chunkState{wait: make(chan struct{})}
+			a.mu.Unlock()
+			return true, nil
+		}
+		if st.done {
+			a.mu.Unlock()
+			return false, nil
+		}
+		wait := st.wait
+		a.mu.Unlock()
+
+		select {
+		case <-wait:
+			// The in-flight attempt resolved; loop around to see whether it committed or was
+			// abandoned.
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// commit marks (sessionID, seq) durably written and wakes any attempt blocked in begin.
+func (a *chunkAcks) commit(sessionID, seq int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if seqs, ok := a.state[sessionID]; ok {
+		if st, ok := seqs[seq]; ok && !st.done {
+			st.done = true
+			close(st.wait)
+		}
+	}
+}
+
+// abandon drops the in-flight marker for (sessionID, seq) after a failed write, so the next
+// attempt (including one already blocked in begin) gets to retry the write itself instead of
+// being told it already succeeded.
+func (a *chunkAcks) abandon(sessionID, seq int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if seqs, ok := a.state[sessionID]; ok {
+		if st, ok := seqs[seq]; ok && !st.done {
+			delete(seqs, seq)
+			close(st.wait)
+		}
+	}
+}
+
+func (a *chunkAcks) forget(sessionID int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.state, sessionID)
+	delete(a.lastSeen, sessionID)
+}
+
+// runSweep periodically evicts bookkeeping for sessions that haven't streamed a chunk in over
+// chunkAckTTL, until ctx is canceled.
+func (a *chunkAcks) runSweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.sweep(now)
+		}
+	}
+}
+
+func (a *chunkAcks) sweep(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for sessionID, last := range a.lastSeen {
+		if now.Sub(last) > chunkAckTTL {
+			delete(a.state, sessionID)
+			delete(a.lastSeen, sessionID)
+		}
+	}
+}
+
+// legacyChunkSeq hands out a distinct, negative chunk seq for every compatibility-shim call
+// from PushPayload, since unlike a real PushPayloadChunk stream each PushPayload call is a
+// one-off batch that must never be deduped against a prior call for the same session. The
+// counter starts below the negated process start time in nanoseconds rather than at 0, so a
+// restarted process doesn't hand out seqs (and object keys) that an earlier process already used.
+var legacyChunkSeq = -time.Now().UnixNano()
+
+func nextLegacyChunkSeq() int {
+	return int(atomic.AddInt64(&legacyChunkSeq, -1))
+}
+
+// PushPayloadChunk lets the client SDK stream rrweb events in small batches while the session
+// is still live, instead of buffering the whole session and calling PushPayload once at the
+// end. Each chunk is decoded and written independently of the others, keyed by seq, so a
+// dropped chunk can be retried without duplicating events.
+func (r *mutationResolver) PushPayloadChunk(ctx context.Context, sessionID int, seq int, payload io.Reader) (*int, error) {
+	proceed, err := pushPayloadChunkAcks.begin(ctx, sessionID, seq)
+	if err != nil {
+		return nil, e.Wrap(err, "error waiting for in-flight chunk write")
+	}
+	if !proceed {
+		return &sessionID, nil
+	}
+
+	sessionObj := &model.Session{}
+	if err := r.DB.WithContext(ctx).Where(&model.Session{Model: model.Model{ID: sessionID}}).First(&sessionObj).Error; err != nil {
+		pushPayloadChunkAcks.abandon(sessionID, seq)
+		return nil, e.Wrap(err, "error reading from session")
+	}
+
+	if err := r.writeEventsChunk(ctx, sessionID, sessionObj.OrganizationID, seq, payload); err != nil {
+		pushPayloadChunkAcks.abandon(sessionID, seq)
+		return nil, err
+	}
+	pushPayloadChunkAcks.commit(sessionID, seq)
+	return &sessionID, nil
+}
+
+// writeEventsChunk decodes and durably stores a single chunk of events. It's shared by
+// PushPayloadChunk (which dedupes retried chunks by seq) and PushPayload's compatibility shim
+// (which already has sessionObj in hand and never needs dedup, since each call is a distinct
+// one-off batch, not a retry).
+func (r *mutationResolver) writeEventsChunk(ctx context.Context, sessionID int, organizationID int, seq int, payload io.Reader) error {
+	normalized, err := decodeAndNormalizeChunk(ctx, payload)
+	if err != nil {
+		return e.Wrap(err, "error decoding payload chunk")
+	}
+
+	pointer, err := r.PayloadStore.PutPayload(ctx, storage.PayloadKey{
+		OrganizationID: organizationID,
+		SessionID:      sessionID,
+		ChunkSeq:       seq,
+		Kind:           storage.PayloadKindEvents,
+	}, normalized)
+	if err != nil {
+		return e.Wrap(err, "error writing payload chunk to store")
+	}
+
+	obj := &model.EventsObject{SessionID: sessionID, OrganizationID: organizationID, ChunkSeq: seq, Bucket: pointer.Bucket, Key: pointer.Key, Size: pointer.Size, ContentEncoding: pointer.ContentEncoding, Sha256: pointer.Sha256}
+	if err := r.DB.WithContext(ctx).Create(obj).Error; err != nil {
+		return e.Wrap(err, "error creating events object for chunk")
+	}
+	return nil
+}
+
+// FinalizePayload tells the server the client has finished streaming chunks through seq for
+// sessionID. It only has to flip PayloadUpdatedAt and drop the session's ack bookkeeping; every
+// chunk has already been durably written by PushPayloadChunk.
+func (r *mutationResolver) FinalizePayload(ctx context.Context, sessionID int, seq int) (*int, error) {
+	now := time.Now()
+	if err := r.DB.WithContext(ctx).Model(&model.Session{Model: model.Model{ID: sessionID}}).Updates(&model.Session{PayloadUpdatedAt: &now}).Error; err != nil {
+		return nil, e.Wrap(err, "error finalizing payload")
+	}
+	pushPayloadChunkAcks.forget(sessionID)
+	return &sessionID, nil
+}
+
+// decodeAndNormalizeChunk decodes a JSON array of rrweb events one event at a time with
+// json.Decoder rather than json.Marshal-ing the whole batch, and injects stylesheets only into
+// FullSnapshot events without re-marshaling their siblings.
+func decodeAndNormalizeChunk(ctx context.Context, payload io.Reader) ([]byte, error) {
+	dec := json.NewDecoder(payload)
+
+	if _, err := dec.Token(); err != nil { // consume the leading '['
+		return nil, e.Wrap(err, "error reading chunk array start")
+	}
+
+	var out bytes.Buffer
+	out.WriteByte('[')
+	first := true
+	for dec.More() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, e.Wrap(err, "error decoding event from chunk")
+		}
+
+		raw = injectStylesheetsIntoEvent(ctx, raw)
+
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		out.Write(raw)
+	}
+	out.WriteByte(']')
+	return out.Bytes(), nil
+}
+
+// injectStylesheetsIntoEvent re-marshals only the single event object passed in, not the rest
+// of the chunk, when it's a FullSnapshot.
+func injectStylesheetsIntoEvent(ctx context.Context, raw json.RawMessage) json.RawMessage {
+	var probe struct {
+		Type int             `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil || probe.Type != parse.FullSnapshot {
+		return raw
+	}
+
+	d, err := injectStylesheetsWithDeadline(ctx, string(probe.Data))
+	if err != nil {
+		return raw
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return raw
+	}
+	dataBytes, err := json.Marshal(d)
+	if err != nil {
+		return raw
+	}
+	fields["data"] = dataBytes
+
+	reRaw, err := json.Marshal(fields)
+	if err != nil {
+		return raw
+	}
+	return reRaw
+}
@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeAndNormalizeChunkPreservesOrder(t *testing.T) {
+	input := `[{"type":3,"data":{"a":1}},{"type":3,"data":{"a":2}}]`
+
+	out, err := decodeAndNormalizeChunk(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("decodeAndNormalizeChunk returned error: %v", err)
+	}
+
+	var events []map[string]interface{}
+	if err := json.Unmarshal(out, &events); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0]["data"].(map[string]interface{})["a"].(float64) != 1 {
+		t.Errorf("events[0] out of order: %v", events[0])
+	}
+}
+
+func TestDecodeAndNormalizeChunkEmptyArray(t *testing.T) {
+	out, err := decodeAndNormalizeChunk(context.Background(), strings.NewReader(`[]`))
+	if err != nil {
+		t.Fatalf("decodeAndNormalizeChunk returned error: %v", err)
+	}
+	if string(out) != "[]" {
+		t.Errorf("output = %q, want %q", out, "[]")
+	}
+}
+
+func TestDecodeAndNormalizeChunkRejectsMalformedInput(t *testing.T) {
+	if _, err := decodeAndNormalizeChunk(context.Background(), strings.NewReader(`not json`)); err == nil {
+		t.Fatal("expected an error decoding malformed input")
+	}
+}
+
+func TestDecodeAndNormalizeChunkRespectsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := decodeAndNormalizeChunk(ctx, strings.NewReader(`[{"type":3},{"type":3}]`)); err == nil {
+		t.Fatal("expected decodeAndNormalizeChunk to stop once ctx is canceled")
+	}
+}
+
+func TestChunkAcksSecondBeginAfterCommitDoesNotProceed(t *testing.T) {
+	a := &chunkAcks{state: make(map[int]map[int]*chunkState), lastSeen: make(map[int]time.Time)}
+
+	proceed, err := a.begin(context.Background(), 1, 1)
+	if err != nil || !proceed {
+		t.Fatalf("first begin: proceed=%v err=%v, want true, nil", proceed, err)
+	}
+	a.commit(1, 1)
+
+	proceed, err = a.begin(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("second begin returned error: %v", err)
+	}
+	if proceed {
+		t.Error("second begin must not proceed once the chunk was committed")
+	}
+}
+
+// TestChunkAcksRetryAfterFailedWriteCanProceed is the regression test for the data-loss bug: a
+// failed write must not leave the chunk permanently marked as written, since the SDK's documented
+// recovery from a failed PushPayloadChunk call is to retry the same (sessionID, seq).
+func TestChunkAcksRetryAfterFailedWriteCanProceed(t *testing.T) {
+	a := &chunkAcks{state: make(map[int]map[int]*chunkState), lastSeen: make(map[int]time.Time)}
+
+	proceed, err := a.begin(context.Background(), 1, 1)
+	if err != nil || !proceed {
+		t.Fatalf("first begin: proceed=%v err=%v, want true, nil", proceed, err)
+	}
+	a.abandon(1, 1) // simulates writeEventsChunk failing
+
+	proceed, err = a.begin(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("retry begin returned error: %v", err)
+	}
+	if !proceed {
+		t.Fatal("a retry after a failed write must be allowed to write the chunk itself, or the event is lost forever")
+	}
+}
+
+func TestChunkAcksConcurrentBeginSerializes(t *testing.T) {
+	a := &chunkAcks{state: make(map[int]map[int]*chunkState), lastSeen: make(map[int]time.Time)}
+
+	first, err := a.begin(context.Background(), 1, 1)
+	if err != nil || !first {
+		t.Fatalf("first begin: proceed=%v err=%v, want true, nil", first, err)
+	}
+
+	type result struct {
+		proceed bool
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		proceed, err := a.begin(context.Background(), 1, 1)
+		done <- result{proceed, err}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("concurrent begin must block while the first attempt is still in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	a.commit(1, 1)
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("blocked begin returned error: %v", res.err)
+		}
+		if res.proceed {
+			t.Error("blocked begin must see the committed result, not proceed to write again")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked begin never returned after commit")
+	}
+}
+
+func TestChunkAcksForgetDropsBookkeeping(t *testing.T) {
+	a := &chunkAcks{state: make(map[int]map[int]*chunkState), lastSeen: make(map[int]time.Time)}
+
+	proceed, _ := a.begin(context.Background(), 1, 1)
+	if !proceed {
+		t.Fatal("expected first begin to proceed")
+	}
+	a.commit(1, 1)
+	a.forget(1)
+
+	proceed, err := a.begin(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("begin after forget returned error: %v", err)
+	}
+	if !proceed {
+		t.Error("begin after forget should treat the session as never having streamed this chunk")
+	}
+}
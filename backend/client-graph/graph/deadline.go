@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	parse "github.com/highlight-run/highlight/backend/event-parse"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultPushPayloadDeadline is the fallback used when PUSH_PAYLOAD_DEADLINE isn't set (or
+// doesn't parse), bounding how long PushPayload may run before we give up and ask the client to
+// retry, the same read/write deadline pattern net.Conn uses.
+const defaultPushPayloadDeadline = 30 * time.Second
+
+// pushPayloadDeadlineEnvVar overrides defaultPushPayloadDeadline, parsed as a time.ParseDuration
+// string (e.g. "45s"), so the deadline can be tuned per deployment without a code change. It is
+// read once at startup, so a new value takes effect only after a process restart.
+const pushPayloadDeadlineEnvVar = "PUSH_PAYLOAD_DEADLINE"
+
+// pushPayloadDeadline is resolved once at process startup from pushPayloadDeadlineEnvVar.
+var pushPayloadDeadline = loadPushPayloadDeadline()
+
+func loadPushPayloadDeadline() time.Duration {
+	raw := os.Getenv(pushPayloadDeadlineEnvVar)
+	if raw == "" {
+		return defaultPushPayloadDeadline
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		log.WithError(err).Warnf("graph: invalid %s %q, falling back to %s", pushPayloadDeadlineEnvVar, raw, defaultPushPayloadDeadline)
+		return defaultPushPayloadDeadline
+	}
+	return d
+}
+
+// injectStylesheetsEventDeadline bounds a single FullSnapshot event's stylesheet injection so
+// one slow CORS fetch can't consume the whole payload's deadline.
+const injectStylesheetsEventDeadline = 2 * time.Second
+
+// DeadlineExceededError is returned to the client when a mutation's context deadline fires
+// mid-flight. RetryHint tells SDKs it's safe to retry the call.
+type DeadlineExceededError struct {
+	Operation string
+	RetryHint bool
+}
+
+func (err *DeadlineExceededError) Error() string {
+	return err.Operation + ": deadline exceeded, please retry"
+}
+
+func (err *DeadlineExceededError) Timeout() bool   { return true }
+func (err *DeadlineExceededError) Temporary() bool { return true }
+
+// withPushPayloadDeadline derives a context bounded by pushPayloadDeadline, so a slow client or
+// hung downstream can't pin PushPayload's goroutine indefinitely.
+func withPushPayloadDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, pushPayloadDeadline)
+}
+
+// asDeadlineError converts a context deadline error into the structured DeadlineExceededError
+// GraphQL clients can detect and retry on, leaving every other error untouched.
+func asDeadlineError(operation string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &DeadlineExceededError{Operation: operation, RetryHint: true}
+	}
+	return err
+}
+
+// injectStylesheetsWithDeadline runs parse.InjectStylesheets, which has no context.Context
+// parameter of its own, on a goroutine so a per-event deadline can still bound it. On timeout the
+// caller returns at once, but the goroutine keeps running until parse.InjectStylesheets returns;
+// done is buffered, so it can then deliver its result and exit with no receiver. A call to
+// parse.InjectStylesheets that never returns leaves its goroutine (and the drain goroutine)
+// running for the life of the process.
+func injectStylesheetsWithDeadline(ctx context.Context, data string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, injectStylesheetsEventDeadline)
+	defer cancel()
+
+	type result struct {
+		data string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		d, err := parse.InjectStylesheets(data)
+		done <- result{d, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.data, res.err
+	case <-ctx.Done():
+		go func() { <-done }()
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,202 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/highlight-run/highlight/backend/alerts"
+	customModels "github.com/highlight-run/highlight/backend/client-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/model"
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errorNotification is a single non-localhost error queued for an async alert-sink notification.
+type errorNotification struct {
+	group *model.ErrorGroup
+	url   string
+}
+
+// errorOccurrence is one raw error in the payload, paired with its already-marshaled trace so
+// we don't re-marshal it once for dedup and again for the insert row.
+type errorOccurrence struct {
+	input       *customModels.ErrorObjectInput
+	traceString string
+}
+
+// occurrenceFingerprint is a within-payload dedup key (type+event+trace) so a page that throws
+// the same error hundreds of times in one push counts as one group occurrence bump instead of
+// hundreds of individual increments. It is never persisted and is unrelated to the
+// (organization, type, event) key ErrorGroup is upserted on.
+func occurrenceFingerprint(v *customModels.ErrorObjectInput) (string, error) {
+	traceBytes, err := json.Marshal(v.Trace)
+	if err != nil {
+		return "", e.Wrap(err, "error marshaling trace for fingerprint")
+	}
+	h := sha256.New()
+	h.Write([]byte(v.Type))
+	h.Write([]byte(v.Event))
+	h.Write(traceBytes)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// putErrorsToDB de-dupes the errors pushed in a single payload by occurrenceFingerprint, bulk
+// upserts one ErrorGroup row per distinct (type, event) (merging fingerprints that only differ
+// by trace), then bulk-inserts the resulting ErrorObject and ErrorField rows, so a page that
+// throws hundreds of errors in one push — whether hundreds of repeats of the same error or
+// hundreds of distinct ones — runs a handful of statements instead of one round trip per error.
+func (r *mutationResolver) putErrorsToDB(ctx context.Context, organizationID int, sessionID int, sessionObj *model.Session, errorInputs []*customModels.ErrorObjectInput) error {
+	putErrorsToDBSpan, _ := tracer.StartSpanFromContext(ctx, "client-graph.pushPayload", tracer.ResourceName("db.errors"))
+	defer putErrorsToDBSpan.Finish()
+
+	if len(errorInputs) == 0 {
+		return nil
+	}
+
+	occurrencesByFingerprint := make(map[string][]*errorOccurrence, len(errorInputs))
+	var fingerprintOrder []string
+
+	for _, v := range errorInputs {
+		fingerprint, err := occurrenceFingerprint(v)
+		if err != nil {
+			log.Errorf("error computing fingerprint for error: %v", v)
+			continue
+		}
+		traceBytes, err := json.Marshal(v.Trace)
+		if err != nil {
+			log.Errorf("Error marshaling trace: %v", v.Trace)
+			continue
+		}
+		if _, ok := occurrencesByFingerprint[fingerprint]; !ok {
+			fingerprintOrder = append(fingerprintOrder, fingerprint)
+		}
+		occurrencesByFingerprint[fingerprint] = append(occurrencesByFingerprint[fingerprint], &errorOccurrence{input: v, traceString: string(traceBytes)})
+	}
+
+	// Upsert one ErrorGroup row per distinct (type, event) in a single statement instead of a
+	// FirstOrCreate+UpdateColumn round trip per group, so a push with hundreds of distinct errors
+	// still costs one statement. Grouping is by (type, event) only, coarser than
+	// occurrenceFingerprint's (type, event, trace): two occurrenceFingerprints that share a
+	// (type, event) but differ in trace (same error thrown from two call sites) must still merge
+	// into one ErrorGroup row here, or they'd collide on idx_error_groups_org_type_event within
+	// the same CreateInBatches statement and Postgres would reject the whole batch ("ON CONFLICT
+	// DO UPDATE command cannot affect row a second time"). The conflict target is the
+	// (organization_id, type, event) unique index on ErrorGroup; on conflict, occurrences
+	// accumulates by this push's merged count rather than clobbering the existing total, and
+	// trace/state are left untouched.
+	groups := make([]*model.ErrorGroup, 0, len(fingerprintOrder))
+	groupIndexByKey := make(map[string]int, len(fingerprintOrder))
+	groupIndexByFingerprint := make(map[string]int, len(fingerprintOrder))
+	for _, fingerprint := range fingerprintOrder {
+		occurrences := occurrencesByFingerprint[fingerprint]
+		first := occurrences[0]
+		key := first.input.Type + "\x00" + first.input.Event
+
+		idx, ok := groupIndexByKey[key]
+		if !ok {
+			idx = len(groups)
+			groups = append(groups, &model.ErrorGroup{
+				OrganizationID: organizationID,
+				Type:           first.input.Type,
+				Event:          first.input.Event,
+				Trace:          first.traceString,
+				State:          "OPEN",
+			})
+			groupIndexByKey[key] = idx
+		}
+		groups[idx].Occurrences += len(occurrences)
+		groupIndexByFingerprint[fingerprint] = idx
+	}
+
+	if err := r.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}, {Name: "type"}, {Name: "event"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"occurrences": gorm.Expr("error_groups.occurrences + excluded.occurrences"),
+		}),
+	}).CreateInBatches(groups, 500).Error; err != nil {
+		return e.Wrap(err, "error bulk upserting error groups")
+	}
+
+	errorsToInsert := make([]*model.ErrorObject, 0, len(errorInputs))
+	fieldsToInsert := make([]*model.ErrorField, 0, len(errorInputs)*4)
+	var notifications []errorNotification
+
+	for _, fingerprint := range fingerprintOrder {
+		occurrences := occurrencesByFingerprint[fingerprint]
+		group := groups[groupIndexByFingerprint[fingerprint]]
+
+		for _, occ := range occurrences {
+			errorsToInsert = append(errorsToInsert, &model.ErrorObject{
+				OrganizationID: organizationID,
+				SessionID:      sessionID,
+				ErrorGroupID:   group.ID,
+				Event:          occ.input.Event,
+				Type:           occ.input.Type,
+				URL:            occ.input.URL,
+				Source:         occ.input.Source,
+				LineNumber:     occ.input.LineNumber,
+				ColumnNumber:   occ.input.ColumnNumber,
+				OS:             sessionObj.OSName,
+				Browser:        sessionObj.BrowserName,
+				Trace:          &occ.traceString,
+				Timestamp:      occ.input.Timestamp,
+			})
+
+			fieldsToInsert = append(fieldsToInsert,
+				&model.ErrorField{OrganizationID: organizationID, ErrorGroupID: group.ID, Name: "browser", Value: sessionObj.BrowserName},
+				&model.ErrorField{OrganizationID: organizationID, ErrorGroupID: group.ID, Name: "os_name", Value: sessionObj.OSName},
+				&model.ErrorField{OrganizationID: organizationID, ErrorGroupID: group.ID, Name: "visited_url", Value: occ.input.URL},
+				&model.ErrorField{OrganizationID: organizationID, ErrorGroupID: group.ID, Name: "event", Value: occ.input.Event},
+			)
+
+			if !strings.Contains(occ.input.URL, "localhost") {
+				notifications = append(notifications, errorNotification{group: group, url: occ.input.URL})
+			}
+		}
+	}
+
+	if err := r.DB.WithContext(ctx).CreateInBatches(errorsToInsert, 500).Error; err != nil {
+		return e.Wrap(err, "error batch inserting error objects")
+	}
+
+	if len(fieldsToInsert) > 0 {
+		if err := r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(fieldsToInsert, 500).Error; err != nil {
+			return e.Wrap(err, "error batch inserting error fields")
+		}
+	}
+
+	// Notify off of the mutation's goroutine so a slow sink can't stall the DB work above; a
+	// panic inside a Sink.Notify implementation shouldn't take the whole process down with it.
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Errorf("panic while sending error notifications: %v", rec)
+			}
+		}()
+		r.sendErrorNotifications(context.Background(), sessionID, sessionObj.Identifier, notifications)
+	}()
+
+	return nil
+}
+
+func (r *mutationResolver) sendErrorNotifications(ctx context.Context, sessionID int, identifier string, notifications []errorNotification) {
+	for _, n := range notifications {
+		if err := r.Alerts.Notify(ctx, n.group.OrganizationID, alerts.Event{
+			Kind:           alerts.EventKindError,
+			OrganizationID: n.group.OrganizationID,
+			SessionID:      sessionID,
+			Identifier:     identifier,
+			URL:            n.url,
+			Title:          "New error",
+			Message:        n.group.Event,
+		}); err != nil {
+			log.Errorf("Error notifying alert sinks: %v", err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package graph
+
+// This file will not be regenerated automatically.
+//
+// It serves as dependency injection for your app, add any dependencies you require here.
+
+import (
+	"context"
+
+	"github.com/highlight-run/highlight/backend/alerts"
+	"github.com/highlight-run/highlight/backend/bloomfilter"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/storage"
+	e "github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Resolver is the root GraphQL resolver. Every mutation/query resolver reaches its dependencies
+// through this struct via the embedding mutationResolver/queryResolver.
+type Resolver struct {
+	DB                *gorm.DB
+	PayloadStore      storage.PayloadStore
+	Alerts            *alerts.Manager
+	IdentifierFilters *bloomfilter.Registry
+}
+
+// NewResolver wires the Resolver's dependencies once at startup.
+func NewResolver(db *gorm.DB, payloadStore storage.PayloadStore, alertsManager *alerts.Manager, identifierFilters *bloomfilter.Registry) *Resolver {
+	return &Resolver{
+		DB:                db,
+		PayloadStore:      payloadStore,
+		Alerts:            alertsManager,
+		IdentifierFilters: identifierFilters,
+	}
+}
+
+// AppendProperties looks up sessionID's organization and stores each entry in properties as a
+// model.Field of the given type, keyed to the session by SessionID. It takes ctx so
+// IdentifySession/AddTrackProperties/AddSessionProperties stay bounded by the same deadline as
+// the rest of their request.
+func (r *Resolver) AppendProperties(ctx context.Context, sessionID int, properties map[string]string, propType string) error {
+	session := &model.Session{}
+	if err := r.DB.WithContext(ctx).Where(&model.Session{Model: model.Model{ID: sessionID}}).First(&session).Error; err != nil {
+		return e.Wrap(err, "error querying session by sessionID")
+	}
+
+	fields := make([]*model.Field, 0, len(properties))
+	for k, v := range properties {
+		fields = append(fields, &model.Field{
+			OrganizationID: session.OrganizationID,
+			SessionID:      sessionID,
+			Type:           propType,
+			Name:           k,
+			Value:          v,
+		})
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if err := r.DB.WithContext(ctx).Create(&fields).Error; err != nil {
+		return e.Wrap(err, "error creating fields")
+	}
+
+	return nil
+}
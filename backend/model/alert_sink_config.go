@@ -0,0 +1,15 @@
+package model
+
+// AlertSinkConfig is one organization's configured alert destination (Slack/Discord/PagerDuty/
+// Teams/generic webhook), loaded by alerts.RouterLoader to build that organization's Router.
+type AlertSinkConfig struct {
+	Model
+	OrganizationID int
+	Kind           string // "slack", "discord", "pagerduty", "teams", or "webhook"
+	Name           string
+	URL            string
+	Secret         string // used by "webhook"; the routing key for "pagerduty"
+	RatePerSec     float64
+	RetryCount     int
+	Enabled        bool
+}
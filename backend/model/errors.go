@@ -0,0 +1,43 @@
+package model
+
+// ErrorGroup is the persisted identity a set of matching ErrorObject occurrences collapse
+// onto, along with the bookkeeping that survives across occurrences (resolved/snoozed state,
+// how many times it's been seen).
+type ErrorGroup struct {
+	Model
+	OrganizationID int    `gorm:"uniqueIndex:idx_error_groups_org_type_event"`
+	Event          string `gorm:"uniqueIndex:idx_error_groups_org_type_event"`
+	Type           string `gorm:"uniqueIndex:idx_error_groups_org_type_event"`
+	Trace          string
+	State          string // "OPEN", "RESOLVED", or "IGNORED"; defaults to "OPEN" on creation
+	Occurrences    int
+}
+
+// ErrorObject is a single occurrence of an error reported by PushPayload, belonging to an
+// ErrorGroup.
+type ErrorObject struct {
+	Model
+	OrganizationID int
+	SessionID      int
+	ErrorGroupID   int
+	Event          string
+	Type           string
+	URL            string
+	Source         string
+	LineNumber     int
+	ColumnNumber   int
+	OS             string
+	Browser        string
+	Trace          *string
+	Timestamp      int64
+}
+
+// ErrorField is a searchable key/value attribute (browser, os_name, visited_url, event) of the
+// ErrorGroup it belongs to.
+type ErrorField struct {
+	Model
+	OrganizationID int
+	ErrorGroupID   int
+	Name           string
+	Value          string
+}
@@ -0,0 +1,70 @@
+// Package model holds the Postgres-backed row types shared by the client-graph resolvers.
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Model is the common embedded base for every table in this package, mirroring gorm.Model but
+// with an int ID since that's what every resolver already keys sessions and objects by.
+type Model struct {
+	ID        int `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+}
+
+// F and T are addressable false/true so callers that need a *bool (e.g. Session.FirstTime)
+// don't have to spell out a local variable at every call site.
+var F = false
+var T = true
+
+// Session is one user session pushed by the client SDK.
+type Session struct {
+	Model
+	OrganizationID   int
+	Identifier       string
+	FirstTime        *bool
+	OSName           string
+	BrowserName      string
+	PayloadUpdatedAt *time.Time
+}
+
+// Field is a single key/value property attached to a session, e.g. a user or track property
+// set by IdentifySession/AddSessionProperties/AddTrackProperties.
+type Field struct {
+	Model
+	OrganizationID int
+	SessionID      int
+	Type           string
+	Name           string
+	Value          string
+}
+
+// DailyErrorCount tracks how many errors an organization saw on a given UTC day, incremented by
+// PushPayload.
+type DailyErrorCount struct {
+	Model
+	OrganizationID int
+	Date           *time.Time
+	Count          int
+}
+
+// Migrate runs AutoMigrate for every table this package defines. It's meant to be called once
+// from the service's startup code before the resolvers start taking traffic.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&Session{},
+		&Field{},
+		&DailyErrorCount{},
+		&EventsObject{},
+		&MessagesObject{},
+		&ResourcesObject{},
+		&ErrorGroup{},
+		&ErrorObject{},
+		&ErrorField{},
+		&AlertSinkConfig{},
+	)
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/highlight-run/highlight/backend/storage"
+)
+
+// readPayload returns row's payload bytes: the legacy inline column (inline) if the row predates
+// the PayloadStore migration (no Bucket was ever written for it), or a PayloadStore.GetPayload
+// read using the row's Bucket/Key/Size/ContentEncoding/Sha256 pointer otherwise. This is the read
+// counterpart to PayloadKey/InlinePayload (used by storage.Migrator to write rows into the
+// store), and is what player-facing code should call instead of reading the Events/Messages/
+// Resources column directly, since that column is empty for any row written after the migration.
+func readPayload(ctx context.Context, store storage.PayloadStore, bucket, key string, size int64, contentEncoding, sha256 string, inline []byte) ([]byte, error) {
+	if bucket == "" {
+		return inline, nil
+	}
+	return store.GetPayload(ctx, storage.PayloadPointer{Bucket: bucket, Key: key, Size: size, ContentEncoding: contentEncoding, Sha256: sha256})
+}
+
+// EventsObject is one chunk of rrweb events for a session. Events holds the legacy inline JSON
+// payload and is empty for any row written after the PayloadStore migration; Bucket/Key/Size/
+// ContentEncoding/Sha256 are the PayloadPointer persisted in its place. Events rows written before
+// chunked ingestion store the payload wrapped as {"events": [...]}; every row written since (both
+// PushPayload's compatibility shim and a real PushPayloadChunk stream) stores a bare [...] array.
+// normalizeEventsPayload irons out that difference so callers only ever see the bare-array shape.
+type EventsObject struct {
+	Model
+	SessionID       int
+	OrganizationID  int
+	ChunkSeq        int
+	Events          string
+	Bucket          string
+	Key             string
+	Size            int64
+	ContentEncoding string
+	Sha256          string
+}
+
+// PayloadKey locates this row's blob in a PayloadStore, for storage.InlinePayloadRow.
+func (o *EventsObject) PayloadKey() storage.PayloadKey {
+	return storage.PayloadKey{OrganizationID: o.OrganizationID, SessionID: o.SessionID, ChunkSeq: o.ChunkSeq, Kind: storage.PayloadKindEvents}
+}
+
+// InlinePayload returns the legacy inline payload still awaiting migration, normalized to the
+// bare-array shape so a row the Migrator copies into the PayloadStore lands in the same format
+// as every row PushPayloadChunk writes directly, for storage.InlinePayloadRow.
+func (o *EventsObject) InlinePayload() []byte { return normalizeEventsPayload([]byte(o.Events)) }
+
+// Payload returns this chunk's rrweb events as a bare JSON array, reading from store if the row
+// has been migrated to the PayloadStore or returning the legacy inline column otherwise -
+// normalized in both cases, since a row already migrated from the pre-chunking wrapped shape by
+// an older build of the Migrator may still have the wrapper baked into its stored blob.
+func (o *EventsObject) Payload(ctx context.Context, store storage.PayloadStore) ([]byte, error) {
+	data, err := readPayload(ctx, store, o.Bucket, o.Key, o.Size, o.ContentEncoding, o.Sha256, []byte(o.Events))
+	if err != nil {
+		return nil, err
+	}
+	return normalizeEventsPayload(data), nil
+}
+
+// normalizeEventsPayload converts the pre-chunking {"events": [...]} wrapper into the bare [...]
+// array every reader is expected to receive; data that's already a bare array (or doesn't match
+// the wrapper shape) is returned unchanged.
+func normalizeEventsPayload(data []byte) []byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return data
+	}
+
+	var wrapped struct {
+		Events json.RawMessage `json:"events"`
+	}
+	if err := json.Unmarshal(trimmed, &wrapped); err != nil || len(wrapped.Events) == 0 {
+		return data
+	}
+	return wrapped.Events
+}
+
+// MessagesObject is the console/network message blob for one PushPayload call. ChunkSeq
+// distinguishes successive pushes for the same session, the same way EventsObject.ChunkSeq does,
+// so a long session's later pushes don't overwrite an earlier push's blob under the same object
+// key.
+type MessagesObject struct {
+	Model
+	SessionID       int
+	OrganizationID  int
+	ChunkSeq        int
+	Messages        string
+	Bucket          string
+	Key             string
+	Size            int64
+	ContentEncoding string
+	Sha256          string
+}
+
+func (o *MessagesObject) PayloadKey() storage.PayloadKey {
+	return storage.PayloadKey{OrganizationID: o.OrganizationID, SessionID: o.SessionID, ChunkSeq: o.ChunkSeq, Kind: storage.PayloadKindMessages}
+}
+
+func (o *MessagesObject) InlinePayload() []byte { return []byte(o.Messages) }
+
+// Payload returns this push's message blob, reading from store if the row has been migrated to
+// the PayloadStore or returning the legacy inline column otherwise.
+func (o *MessagesObject) Payload(ctx context.Context, store storage.PayloadStore) ([]byte, error) {
+	return readPayload(ctx, store, o.Bucket, o.Key, o.Size, o.ContentEncoding, o.Sha256, []byte(o.Messages))
+}
+
+// ResourcesObject is the resource-timing blob for one PushPayload call. ChunkSeq distinguishes
+// successive pushes for the same session, the same way EventsObject.ChunkSeq does, so a long
+// session's later pushes don't overwrite an earlier push's blob under the same object key.
+type ResourcesObject struct {
+	Model
+	SessionID       int
+	OrganizationID  int
+	ChunkSeq        int
+	Resources       string
+	Bucket          string
+	Key             string
+	Size            int64
+	ContentEncoding string
+	Sha256          string
+}
+
+func (o *ResourcesObject) PayloadKey() storage.PayloadKey {
+	return storage.PayloadKey{OrganizationID: o.OrganizationID, SessionID: o.SessionID, ChunkSeq: o.ChunkSeq, Kind: storage.PayloadKindResources}
+}
+
+func (o *ResourcesObject) InlinePayload() []byte { return []byte(o.Resources) }
+
+// Payload returns this push's resource-timing blob, reading from store if the row has been
+// migrated to the PayloadStore or returning the legacy inline column otherwise.
+func (o *ResourcesObject) Payload(ctx context.Context, store storage.PayloadStore) ([]byte, error) {
+	return readPayload(ctx, store, o.Bucket, o.Key, o.Size, o.ContentEncoding, o.Sha256, []byte(o.Resources))
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/highlight-run/highlight/backend/storage"
+)
+
+func TestMessagesObjectPayloadKeyIncludesChunkSeq(t *testing.T) {
+	first := &MessagesObject{OrganizationID: 1, SessionID: 2, ChunkSeq: -1}
+	second := &MessagesObject{OrganizationID: 1, SessionID: 2, ChunkSeq: -2}
+
+	if first.PayloadKey().ObjectKey() == second.PayloadKey().ObjectKey() {
+		t.Fatal("two pushes with distinct ChunkSeq must not collide on the same object key")
+	}
+}
+
+func TestResourcesObjectPayloadKeyIncludesChunkSeq(t *testing.T) {
+	first := &ResourcesObject{OrganizationID: 1, SessionID: 2, ChunkSeq: -1}
+	second := &ResourcesObject{OrganizationID: 1, SessionID: 2, ChunkSeq: -2}
+
+	if first.PayloadKey().ObjectKey() == second.PayloadKey().ObjectKey() {
+		t.Fatal("two pushes with distinct ChunkSeq must not collide on the same object key")
+	}
+}
+
+func TestMessagesObjectPayloadReturnsLegacyInlineColumn(t *testing.T) {
+	o := &MessagesObject{Messages: `{"messages":[]}`}
+
+	got, err := o.Payload(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Payload returned error: %v", err)
+	}
+	if string(got) != o.Messages {
+		t.Errorf("Payload = %q, want the inline Messages column %q", got, o.Messages)
+	}
+}
+
+func TestNormalizeEventsPayloadUnwrapsLegacyShape(t *testing.T) {
+	got := normalizeEventsPayload([]byte(`{"events":[{"type":3}]}`))
+	if string(got) != `[{"type":3}]` {
+		t.Errorf("normalizeEventsPayload = %q, want the bare array %q", got, `[{"type":3}]`)
+	}
+}
+
+func TestNormalizeEventsPayloadLeavesBareArrayUnchanged(t *testing.T) {
+	bare := []byte(`[{"type":3}]`)
+	got := normalizeEventsPayload(bare)
+	if string(got) != string(bare) {
+		t.Errorf("normalizeEventsPayload = %q, want %q unchanged", got, bare)
+	}
+}
+
+func TestEventsObjectPayloadNormalizesLegacyInlineWrapper(t *testing.T) {
+	o := &EventsObject{Events: `{"events":[{"type":3}]}`}
+
+	got, err := o.Payload(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Payload returned error: %v", err)
+	}
+	if string(got) != `[{"type":3}]` {
+		t.Errorf("Payload = %q, want the unwrapped bare array %q", got, `[{"type":3}]`)
+	}
+}
+
+func TestEventsObjectInlinePayloadNormalizesLegacyWrapper(t *testing.T) {
+	o := &EventsObject{Events: `{"events":[{"type":3}]}`}
+
+	got := o.InlinePayload()
+	if string(got) != `[{"type":3}]` {
+		t.Errorf("InlinePayload = %q, want the unwrapped bare array %q (so the Migrator writes the same shape PushPayloadChunk does)", got, `[{"type":3}]`)
+	}
+}
+
+func TestEventsObjectPayloadReadsFromStoreWhenMigrated(t *testing.T) {
+	store := storage.NewLocalPayloadStore(filepath.Join(t.TempDir(), "payloads"))
+	key := storage.PayloadKey{OrganizationID: 1, SessionID: 2, ChunkSeq: 3, Kind: storage.PayloadKindEvents}
+	pointer, err := store.PutPayload(context.Background(), key, []byte(`[{"type":3}]`))
+	if err != nil {
+		t.Fatalf("PutPayload returned error: %v", err)
+	}
+
+	o := &EventsObject{
+		OrganizationID:  1,
+		SessionID:       2,
+		ChunkSeq:        3,
+		Bucket:          pointer.Bucket,
+		Key:             pointer.Key,
+		Size:            pointer.Size,
+		ContentEncoding: pointer.ContentEncoding,
+		Sha256:          pointer.Sha256,
+	}
+
+	got, err := o.Payload(context.Background(), store)
+	if err != nil {
+		t.Fatalf("Payload returned error: %v", err)
+	}
+	if string(got) != `[{"type":3}]` {
+		t.Errorf("Payload = %q, want the bytes written to the store", got)
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"cloud.google.com/go/storage"
+	e "github.com/pkg/errors"
+	"google.golang.org/api/option"
+)
+
+// GCSPayloadStore writes payload blobs to a Google Cloud Storage bucket.
+type GCSPayloadStore struct {
+	bucket string
+	client *storage.Client
+}
+
+func NewGCSPayloadStore(bucket, credentialsFile string) (*GCSPayloadStore, error) {
+	ctx := context.Background()
+	var opts []option.ClientOption
+	if credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, e.Wrap(err, "error creating gcs client")
+	}
+	return &GCSPayloadStore{bucket: bucket, client: client}, nil
+}
+
+func (s *GCSPayloadStore) Bucket() string { return s.bucket }
+
+func (s *GCSPayloadStore) PutPayload(ctx context.Context, key PayloadKey, payload []byte) (*PayloadPointer, error) {
+	compressed, pointer, err := gzipPointer(s.bucket, key, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := withRetry(ctx, 5, func() error {
+		w := s.client.Bucket(s.bucket).Object(pointer.Key).NewWriter(ctx)
+		w.ContentEncoding = pointer.ContentEncoding
+		if _, err := w.Write(compressed); err != nil {
+			return err
+		}
+		return w.Close()
+	}); err != nil {
+		return nil, e.Wrap(err, "error writing payload to gcs")
+	}
+
+	return pointer, nil
+}
+
+func (s *GCSPayloadStore) GetPayload(ctx context.Context, pointer PayloadPointer) ([]byte, error) {
+	var compressed []byte
+	if err := withRetry(ctx, 5, func() error {
+		r, err := s.client.Bucket(pointer.Bucket).Object(pointer.Key).NewReader(ctx)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(r); err != nil {
+			return err
+		}
+		compressed = buf.Bytes()
+		return nil
+	}); err != nil {
+		return nil, e.Wrap(err, "error reading payload from gcs")
+	}
+	return gunzip(compressed)
+}
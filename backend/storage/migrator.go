@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// InlinePayloadRow is satisfied by the existing model.EventsObject / model.MessagesObject /
+// model.ResourcesObject rows whose payload still lives inline in Postgres.
+type InlinePayloadRow interface {
+	PayloadKey() PayloadKey
+	InlinePayload() []byte
+}
+
+// Migrator moves inline payload rows into a PayloadStore in the background, batching so it
+// doesn't compete with live traffic for DB connections.
+type Migrator struct {
+	DB    *gorm.DB
+	Store PayloadStore
+}
+
+func NewMigrator(db *gorm.DB, store PayloadStore) *Migrator {
+	return &Migrator{DB: db, Store: store}
+}
+
+// MigrateBatch migrates every row in rows, returning the number migrated so the caller can
+// loop until it gets back 0. A row whose store write fails is logged and skipped.
+func (m *Migrator) MigrateBatch(ctx context.Context, rows []InlinePayloadRow, markMigrated func(row InlinePayloadRow, pointer *PayloadPointer) error) (int, error) {
+	migrated := 0
+	for _, row := range rows {
+		pointer, err := m.Store.PutPayload(ctx, row.PayloadKey(), row.InlinePayload())
+		if err != nil {
+			log.WithError(err).WithField("key", row.PayloadKey()).Error("migrator: error writing payload to store")
+			continue
+		}
+		if err := markMigrated(row, pointer); err != nil {
+			return migrated, e.Wrap(err, "error marking row as migrated")
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// Run drives the migrator on a ticker until ctx is cancelled. Each tick it fetches up to
+// batchSize un-migrated rows via fetchBatch and keeps calling MigrateBatch until a fetch comes
+// back empty, so a backlog that built up since the last tick drains in one pass instead of
+// trickling in at batchSize rows per interval. Intended to be started as its own goroutine from
+// the service's startup code, e.g. `go migrator.Run(ctx, time.Minute, 500, fetchRows, markRow)`.
+func (m *Migrator) Run(ctx context.Context, interval time.Duration, batchSize int, fetchBatch func(ctx context.Context, limit int) ([]InlinePayloadRow, error), markMigrated func(row InlinePayloadRow, pointer *PayloadPointer) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				rows, err := fetchBatch(ctx, batchSize)
+				if err != nil {
+					log.WithError(err).Error("migrator: error fetching un-migrated rows")
+					break
+				}
+				if len(rows) == 0 {
+					break
+				}
+				migrated, err := m.MigrateBatch(ctx, rows, markMigrated)
+				if err != nil {
+					log.WithError(err).Error("migrator: error migrating batch")
+					break
+				}
+				if migrated == 0 {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	e "github.com/pkg/errors"
+)
+
+// MinIOPayloadStore writes payload blobs to a MinIO (or any S3-compatible) endpoint. It's the
+// backend used by docker-compose / self-hosted deploys that don't have an AWS account.
+type MinIOPayloadStore struct {
+	bucket string
+	client *minio.Client
+}
+
+func NewMinIOPayloadStore(bucket, endpoint string) (*MinIOPayloadStore, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewEnvMinio(),
+		Secure: true,
+	})
+	if err != nil {
+		return nil, e.Wrap(err, "error creating minio client")
+	}
+	return &MinIOPayloadStore{bucket: bucket, client: client}, nil
+}
+
+func (s *MinIOPayloadStore) Bucket() string { return s.bucket }
+
+func (s *MinIOPayloadStore) PutPayload(ctx context.Context, key PayloadKey, payload []byte) (*PayloadPointer, error) {
+	compressed, pointer, err := gzipPointer(s.bucket, key, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := withRetry(ctx, 5, func() error {
+		_, err := s.client.PutObject(ctx, s.bucket, pointer.Key, bytes.NewReader(compressed), int64(len(compressed)),
+			minio.PutObjectOptions{ContentEncoding: pointer.ContentEncoding})
+		return err
+	}); err != nil {
+		return nil, e.Wrap(err, "error writing payload to minio")
+	}
+
+	return pointer, nil
+}
+
+func (s *MinIOPayloadStore) GetPayload(ctx context.Context, pointer PayloadPointer) ([]byte, error) {
+	var compressed []byte
+	if err := withRetry(ctx, 5, func() error {
+		obj, err := s.client.GetObject(ctx, s.bucket, pointer.Key, minio.GetObjectOptions{})
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(obj); err != nil {
+			return err
+		}
+		compressed = buf.Bytes()
+		return nil
+	}); err != nil {
+		return nil, e.Wrap(err, "error reading payload from minio")
+	}
+	return gunzip(compressed)
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	e "github.com/pkg/errors"
+)
+
+// S3PayloadStore writes payload blobs to an AWS S3 bucket.
+type S3PayloadStore struct {
+	bucket string
+	client *s3.S3
+}
+
+func NewS3PayloadStore(bucket, region string) (*S3PayloadStore, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, e.Wrap(err, "error creating aws session")
+	}
+	return &S3PayloadStore{bucket: bucket, client: s3.New(sess)}, nil
+}
+
+func (s *S3PayloadStore) Bucket() string { return s.bucket }
+
+func (s *S3PayloadStore) PutPayload(ctx context.Context, key PayloadKey, payload []byte) (*PayloadPointer, error) {
+	compressed, pointer, err := gzipPointer(s.bucket, key, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := withRetry(ctx, 5, func() error {
+		_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+			Bucket:          aws.String(s.bucket),
+			Key:             aws.String(pointer.Key),
+			Body:            bytes.NewReader(compressed),
+			ContentEncoding: aws.String(pointer.ContentEncoding),
+		})
+		return err
+	}); err != nil {
+		return nil, e.Wrap(err, "error writing payload to s3")
+	}
+
+	return pointer, nil
+}
+
+func (s *S3PayloadStore) GetPayload(ctx context.Context, pointer PayloadPointer) ([]byte, error) {
+	var compressed []byte
+	if err := withRetry(ctx, 5, func() error {
+		out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(pointer.Bucket),
+			Key:    aws.String(pointer.Key),
+		})
+		if err != nil {
+			return err
+		}
+		defer out.Body.Close()
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(out.Body); err != nil {
+			return err
+		}
+		compressed = buf.Bytes()
+		return nil
+	}); err != nil {
+		return nil, e.Wrap(err, "error reading payload from s3")
+	}
+	return gunzip(compressed)
+}
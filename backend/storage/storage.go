@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// PayloadKind identifies which part of a session push payload a blob represents.
+type PayloadKind string
+
+const (
+	PayloadKindEvents    PayloadKind = "events"
+	PayloadKindMessages  PayloadKind = "messages"
+	PayloadKindResources PayloadKind = "resources"
+)
+
+// PayloadKey uniquely locates a payload blob within a PayloadStore.
+type PayloadKey struct {
+	OrganizationID int
+	SessionID      int
+	ChunkSeq       int
+	Kind           PayloadKind
+}
+
+// ObjectKey returns the backend-agnostic key a PayloadStore should write the blob under.
+func (k PayloadKey) ObjectKey() string {
+	return fmt.Sprintf("%d/%d/%d/%s.json.gz", k.OrganizationID, k.SessionID, k.ChunkSeq, k.Kind)
+}
+
+// PayloadPointer is the lightweight row persisted in Postgres in place of the raw payload blob.
+type PayloadPointer struct {
+	Bucket          string
+	Key             string
+	Size            int64
+	ContentEncoding string
+	Sha256          string
+}
+
+// PayloadStore writes and reads session payload blobs (events/messages/resources) so that
+// Postgres only ever has to store a PayloadPointer rather than the raw JSON.
+type PayloadStore interface {
+	// PutPayload gzips payload and writes it to the backend, returning the pointer row to persist.
+	PutPayload(ctx context.Context, key PayloadKey, payload []byte) (*PayloadPointer, error)
+	// GetPayload reads and gunzips the payload located by pointer.
+	GetPayload(ctx context.Context, pointer PayloadPointer) ([]byte, error)
+	Bucket() string
+}
+
+// Backend selects which PayloadStore implementation NewPayloadStore constructs.
+type Backend string
+
+const (
+	BackendLocal Backend = "local"
+	BackendS3    Backend = "s3"
+	BackendMinIO Backend = "minio"
+	BackendGCS   Backend = "gcs"
+)
+
+// Config configures the PayloadStore backend. Bucket is the S3/MinIO/GCS bucket name or, for
+// BackendLocal, the root directory payloads are written under.
+type Config struct {
+	Backend         Backend
+	Bucket          string
+	Region          string // used by BackendS3
+	Endpoint        string // used by BackendMinIO
+	CredentialsFile string // used by BackendGCS
+}
+
+// NewPayloadStore constructs the PayloadStore for cfg.Backend. BackendLocal is the default for
+// local development and requires no external service.
+func NewPayloadStore(cfg Config) (PayloadStore, error) {
+	switch cfg.Backend {
+	case "", BackendLocal:
+		root := cfg.Bucket
+		if root == "" {
+			root = "./local-payload-store"
+		}
+		return NewLocalPayloadStore(root), nil
+	case BackendS3:
+		return NewS3PayloadStore(cfg.Bucket, cfg.Region)
+	case BackendMinIO:
+		return NewMinIOPayloadStore(cfg.Bucket, cfg.Endpoint)
+	case BackendGCS:
+		return NewGCSPayloadStore(cfg.Bucket, cfg.CredentialsFile)
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Backend)
+	}
+}
+
+// gzipPointer compresses payload and computes the sha256 digest shared by every backend's
+// PutPayload implementation.
+func gzipPointer(bucket string, key PayloadKey, payload []byte) (compressed []byte, pointer *PayloadPointer, err error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(payload); err != nil {
+		return nil, nil, e.Wrap(err, "error gzipping payload")
+	}
+	if err := gw.Close(); err != nil {
+		return nil, nil, e.Wrap(err, "error closing gzip writer")
+	}
+
+	sum := sha256.Sum256(payload)
+	return buf.Bytes(), &PayloadPointer{
+		Bucket:          bucket,
+		Key:             key.ObjectKey(),
+		Size:            int64(buf.Len()),
+		ContentEncoding: "gzip",
+		Sha256:          hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// withRetry retries fn with exponential backoff, used by every remote backend so a transient
+// S3/MinIO/GCS hiccup doesn't fail a session push.
+func withRetry(ctx context.Context, attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if i == attempts-1 {
+			break
+		}
+		backoff := time.Duration(math.Pow(2, float64(i))) * 100 * time.Millisecond
+		log.WithError(err).Warnf("storage: attempt %d/%d failed, retrying in %s", i+1, attempts, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return e.Wrap(err, "storage: all retry attempts exhausted")
+}
+
+// LocalPayloadStore writes payloads to disk. It's the default backend for local development.
+type LocalPayloadStore struct {
+	root string
+}
+
+func NewLocalPayloadStore(root string) *LocalPayloadStore {
+	return &LocalPayloadStore{root: root}
+}
+
+func (s *LocalPayloadStore) Bucket() string { return s.root }
+
+func (s *LocalPayloadStore) PutPayload(ctx context.Context, key PayloadKey, payload []byte) (*PayloadPointer, error) {
+	compressed, pointer, err := gzipPointer(s.root, key, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(s.root, pointer.Key)
+	if err := withRetry(ctx, 3, func() error {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, compressed, 0644)
+	}); err != nil {
+		return nil, e.Wrap(err, "error writing payload to local store")
+	}
+
+	return pointer, nil
+}
+
+func (s *LocalPayloadStore) GetPayload(ctx context.Context, pointer PayloadPointer) ([]byte, error) {
+	path := filepath.Join(s.root, pointer.Key)
+	var compressed []byte
+	if err := withRetry(ctx, 3, func() error {
+		var err error
+		compressed, err = ioutil.ReadFile(path)
+		return err
+	}); err != nil {
+		return nil, e.Wrap(err, "error reading payload from local store")
+	}
+	return gunzip(compressed)
+}
+
+func gunzip(compressed []byte) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, e.Wrap(err, "error creating gzip reader")
+	}
+	defer gr.Close()
+	b, err := ioutil.ReadAll(gr)
+	if err != nil {
+		return nil, e.Wrap(err, "error reading gzipped payload")
+	}
+	return b, nil
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestGzipPointer(t *testing.T) {
+	key := PayloadKey{OrganizationID: 1, SessionID: 2, ChunkSeq: 3, Kind: PayloadKindEvents}
+	payload := []byte(`[{"type":1}]`)
+
+	compressed, pointer, err := gzipPointer("my-bucket", key, payload)
+	if err != nil {
+		t.Fatalf("gzipPointer returned error: %v", err)
+	}
+
+	if pointer.Bucket != "my-bucket" {
+		t.Errorf("pointer.Bucket = %q, want %q", pointer.Bucket, "my-bucket")
+	}
+	if pointer.Key != key.ObjectKey() {
+		t.Errorf("pointer.Key = %q, want %q", pointer.Key, key.ObjectKey())
+	}
+	if pointer.ContentEncoding != "gzip" {
+		t.Errorf("pointer.ContentEncoding = %q, want %q", pointer.ContentEncoding, "gzip")
+	}
+
+	got, err := gunzip(compressed)
+	if err != nil {
+		t.Fatalf("gunzip returned error: %v", err)
+	}
+	if string(got) != string(payload) {
+		t.Errorf("round-tripped payload = %q, want %q", got, payload)
+	}
+}
+
+func TestWithRetrySucceedsAfterTransientFailures(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), 3, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withRetry returned error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestWithRetryExhaustsAttempts(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), 2, func() error {
+		attempts++
+		return errors.New("permanent")
+	})
+	if err == nil {
+		t.Fatal("expected error after exhausting all attempts")
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestWithRetryRespectsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err := withRetry(ctx, 5, func() error {
+		attempts++
+		return errors.New("never succeeds")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (should give up after the first failure once ctx is done)", attempts)
+	}
+}
+
+func TestLocalPayloadStoreRoundTrip(t *testing.T) {
+	store := NewLocalPayloadStore(filepath.Join(t.TempDir(), "payloads"))
+	key := PayloadKey{OrganizationID: 1, SessionID: 2, ChunkSeq: 0, Kind: PayloadKindMessages}
+	payload := []byte(`{"messages":[]}`)
+
+	pointer, err := store.PutPayload(context.Background(), key, payload)
+	if err != nil {
+		t.Fatalf("PutPayload returned error: %v", err)
+	}
+
+	got, err := store.GetPayload(context.Background(), *pointer)
+	if err != nil {
+		t.Fatalf("GetPayload returned error: %v", err)
+	}
+	if string(got) != string(payload) {
+		t.Errorf("GetPayload = %q, want %q", got, payload)
+	}
+}
+
+func TestLocalPayloadStoreDistinctChunkSeqDoesNotOverwrite(t *testing.T) {
+	store := NewLocalPayloadStore(t.TempDir())
+
+	first, err := store.PutPayload(context.Background(), PayloadKey{OrganizationID: 1, SessionID: 2, ChunkSeq: -1, Kind: PayloadKindMessages}, []byte("first push"))
+	if err != nil {
+		t.Fatalf("PutPayload(first) returned error: %v", err)
+	}
+	second, err := store.PutPayload(context.Background(), PayloadKey{OrganizationID: 1, SessionID: 2, ChunkSeq: -2, Kind: PayloadKindMessages}, []byte("second push"))
+	if err != nil {
+		t.Fatalf("PutPayload(second) returned error: %v", err)
+	}
+
+	if first.Key == second.Key {
+		t.Fatalf("expected distinct object keys for distinct ChunkSeq values, got %q for both", first.Key)
+	}
+
+	gotFirst, err := store.GetPayload(context.Background(), *first)
+	if err != nil {
+		t.Fatalf("GetPayload(first) returned error: %v", err)
+	}
+	if string(gotFirst) != "first push" {
+		t.Errorf("GetPayload(first) = %q, want %q (second push must not have overwritten it)", gotFirst, "first push")
+	}
+}
+
+// TestGunzipRejectsGarbage is a sanity check that gunzip surfaces a decode error rather than
+// silently returning the raw bytes, so a corrupted local-store file is never mistaken for valid
+// payload data.
+func TestGunzipRejectsGarbage(t *testing.T) {
+	if _, err := gunzip([]byte("not gzip data")); err == nil {
+		t.Fatal("expected an error decoding non-gzip data")
+	}
+}
+
+func TestGzipPointerProducesValidGzip(t *testing.T) {
+	compressed, _, err := gzipPointer("bucket", PayloadKey{Kind: PayloadKindResources}, []byte("hello"))
+	if err != nil {
+		t.Fatalf("gzipPointer returned error: %v", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("gzip.NewReader returned error: %v", err)
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("error reading gzip stream: %v", err)
+	}
+	if string(b) != "hello" {
+		t.Errorf("decompressed = %q, want %q", b, "hello")
+	}
+}